@@ -2,20 +2,420 @@ package backends
 
 import (
 	"context"
+	"encoding/pem"
 	"fmt"
 	"os"
+	"strconv"
+	"strings"
+	"sync"
 	"time"
 
 	"github.com/Azure/azure-sdk-for-go/sdk/azcore"
+	"github.com/Azure/azure-sdk-for-go/sdk/azcore/cloud"
+	"github.com/Azure/azure-sdk-for-go/sdk/azcore/policy"
 	"github.com/Azure/azure-sdk-for-go/sdk/azcore/runtime"
+	"github.com/Azure/azure-sdk-for-go/sdk/azidentity"
+	"github.com/Azure/azure-sdk-for-go/sdk/security/keyvault/azcertificates"
+	"github.com/Azure/azure-sdk-for-go/sdk/security/keyvault/azkeys"
 	"github.com/Azure/azure-sdk-for-go/sdk/security/keyvault/azsecrets"
 	"github.com/argoproj-labs/argocd-vault-plugin/pkg/utils"
 )
 
+// annotationAzKVKind lets a path explicitly declare which Key Vault object
+// type it refers to, overriding the /certificates or /keys suffix convention.
+const annotationAzKVKind = "avp_azkv_kind"
+
+// azKVKind identifies which Key Vault API a given path should be served from.
+type azKVKind string
+
+const (
+	azKVKindSecret      azKVKind = "secret"
+	azKVKindCertificate azKVKind = "certificate"
+	azKVKindKey         azKVKind = "key"
+)
+
+// azureGermanCloud was never given a predefined cloud.Configuration upstream
+// (the SDK only ships Public, China and Government), so it's defined here.
+// Its Services are copied from Public cloud; only the AAD authority host is
+// set here. The Key Vault DNS suffix comes from resolveCloud.
+var azureGermanCloud = cloud.Configuration{
+	ActiveDirectoryAuthorityHost: "https://login.microsoftonline.de/",
+	Services:                     cloud.AzurePublic.Services,
+}
+
+// resolveCloud reads AVP_AZ_CLOUD_NAME and returns the Key Vault DNS suffix
+// and cloud.Configuration for the selected sovereign cloud, defaulting to
+// public.
+func resolveCloud() (string, cloud.Configuration) {
+	switch os.Getenv("AVP_AZ_CLOUD_NAME") {
+	case "azurechina":
+		return "vault.azure.cn", cloud.AzureChina
+	case "azureusgovernment":
+		return "vault.usgovcloudapi.net", cloud.AzureGovernment
+	case "azuregermancloud":
+		return "vault.microsoftazure.de", azureGermanCloud
+	default:
+		return "vault.azure.net", cloud.AzurePublic
+	}
+}
+
+// resolveVaultURL determines the Key Vault endpoint and the Azure cloud to
+// authenticate against for a given vault path. AVP_AZ_CLOUD_NAME selects the
+// sovereign cloud (defaulting to public), and AVP_AZ_KV_ENDPOINT_OVERRIDE lets
+// callers substitute a private-link or otherwise custom DNS suffix while
+// keeping the rest of the cloud configuration (e.g. the AAD authority)
+// intact. The override replaces only the suffix, not the whole URL, so
+// kvpath still distinguishes one vault from another the same way it does
+// against the public DNS suffix.
+func resolveVaultURL(kvpath string) (string, cloud.Configuration, error) {
+	suffix, cloudCfg := resolveCloud()
+
+	if override := os.Getenv("AVP_AZ_KV_ENDPOINT_OVERRIDE"); override != "" {
+		suffix = override
+	}
+
+	return fmt.Sprintf("https://%s.%s", kvpath, suffix), cloudCfg, nil
+}
+
+// defaultVaultScope returns the OAuth scope for Key Vault data-plane access
+// in the currently selected sovereign cloud, for pre-warming the default
+// credential in Login.
+func defaultVaultScope() string {
+	suffix, _ := resolveCloud()
+	return fmt.Sprintf("https://%s/.default", suffix)
+}
+
+const (
+	// defaultAzKVConcurrency bounds how many secrets are fetched at once when
+	// enumerating a vault, overridable via AVP_AZ_KV_CONCURRENCY.
+	defaultAzKVConcurrency = 8
+	// defaultAzKVTimeout bounds a single Key Vault API call, overridable via
+	// AVP_AZ_KV_TIMEOUT.
+	defaultAzKVTimeout = 10 * time.Second
+	// listTimeoutMultiplier gives the paginated listing and its fan-out of
+	// per-secret calls a longer overall deadline than any single call gets.
+	listTimeoutMultiplier = 6
+)
+
+// azKVConcurrency returns the worker pool size for fanning out GetSecret
+// calls during enumeration.
+func azKVConcurrency() int {
+	if v := os.Getenv("AVP_AZ_KV_CONCURRENCY"); v != "" {
+		if n, err := strconv.Atoi(v); err == nil && n > 0 {
+			return n
+		}
+	}
+	return defaultAzKVConcurrency
+}
+
+// azKVTimeout returns the per-call timeout applied to individual Key Vault
+// API calls.
+func azKVTimeout() time.Duration {
+	if v := os.Getenv("AVP_AZ_KV_TIMEOUT"); v != "" {
+		if d, err := time.ParseDuration(v); err == nil && d > 0 {
+			return d
+		}
+	}
+	return defaultAzKVTimeout
+}
+
+// resolveFilterKey applies filter.keyFromTag to a listed entry, returning the
+// key that entry's result should be stored under. skip is true when
+// keyFromTag is set but the entry carries no such tag, so the caller should
+// drop it rather than key it by name. An error is returned if two entries
+// resolve to the same key, mirroring the collision check getSecrets performs
+// for its own worker-pool fan-out.
+func resolveFilterKey(filter azKVFilter, name string, tags map[string]*string, seenKeys map[string]string) (key string, skip bool, err error) {
+	if filter.keyFromTag == "" {
+		return name, false, nil
+	}
+
+	tagValue := tags[filter.keyFromTag]
+	if tagValue == nil {
+		return "", true, nil
+	}
+
+	key = *tagValue
+	if existing, ok := seenKeys[key]; ok {
+		return "", false, fmt.Errorf("azure key vault: entries %q and %q both resolve to key %q via %s; keys must be unique", existing, name, key, annotationAzKVKeyFromTag)
+	}
+	seenKeys[key] = name
+	return key, false, nil
+}
+
+// softFailOnVersionMismatch reports whether err, returned while fetching a
+// specific named entry at version, should be treated as "this name simply
+// doesn't have that version" rather than a hard failure. That's the case
+// whenever a version was requested and the listed entry's own version
+// (versionMatches) already differs from it, since the fetch was always
+// expected to 404 against this particular name.
+func softFailOnVersionMismatch(version string, versionMatches bool) bool {
+	return version != "" && !versionMatches
+}
+
+// azKVListTimeout returns the overall deadline for a paginated listing plus
+// its fan-out of per-secret calls, which needs more headroom than any single
+// call gets from azKVTimeout.
+func azKVListTimeout() time.Duration {
+	return azKVTimeout() * listTimeoutMultiplier
+}
+
+// azKVCacheTTL returns how long GetSecrets/GetIndividualSecret results are
+// cached for. It defaults to 0 (disabled) so existing deployments keep
+// re-fetching on every render unless they opt in via AVP_AZ_KV_CACHE_TTL.
+func azKVCacheTTL() time.Duration {
+	v := os.Getenv("AVP_AZ_KV_CACHE_TTL")
+	if v == "" {
+		return 0
+	}
+	ttl, err := time.ParseDuration(v)
+	if err != nil || ttl <= 0 {
+		return 0
+	}
+	return ttl
+}
+
 // AzureKeyVault is a struct for working with an Azure Key Vault backend
 type AzureKeyVault struct {
-	Credential    azcore.TokenCredential
-	ClientBuilder func(vaultURL string, credential azcore.TokenCredential, options *azsecrets.ClientOptions) (AzSecretsClient, error)
+	Credential                azcore.TokenCredential
+	CredentialProvider        CredentialProvider
+	ResultCache               ResultCache
+	ClientBuilder             func(vaultURL string, credential azcore.TokenCredential, options *azsecrets.ClientOptions) (AzSecretsClient, error)
+	CertificatesClientBuilder func(vaultURL string, credential azcore.TokenCredential, options *azcertificates.ClientOptions) (AzCertificatesClient, error)
+	KeysClientBuilder         func(vaultURL string, credential azcore.TokenCredential, options *azkeys.ClientOptions) (AzKeysClient, error)
+}
+
+// ResultCache stores GetSecrets/GetIndividualSecret results keyed by an
+// opaque string the caller builds from the vault, path and version, so an
+// alternative backing store (e.g. a shared Redis) can be plugged in later
+// without touching the lookup logic. Negative lookups are cached too, so a
+// "not found" stays cached with its own (typically shorter) ttl.
+type ResultCache interface {
+	Get(key string) (value interface{}, negative bool, hit bool)
+	Set(key string, value interface{}, negative bool, ttl time.Duration)
+}
+
+type resultCacheEntry struct {
+	value     interface{}
+	negative  bool
+	expiresAt time.Time
+}
+
+// inMemoryResultCache is the default ResultCache: a mutex-guarded map with
+// expired entries evicted lazily on read.
+type inMemoryResultCache struct {
+	mu      sync.Mutex
+	entries map[string]resultCacheEntry
+}
+
+func newInMemoryResultCache() *inMemoryResultCache {
+	return &inMemoryResultCache{entries: make(map[string]resultCacheEntry)}
+}
+
+func (c *inMemoryResultCache) Get(key string) (interface{}, bool, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	entry, ok := c.entries[key]
+	if !ok || time.Now().After(entry.expiresAt) {
+		delete(c.entries, key)
+		return nil, false, false
+	}
+	return entry.value, entry.negative, true
+}
+
+func (c *inMemoryResultCache) Set(key string, value interface{}, negative bool, ttl time.Duration) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.entries[key] = resultCacheEntry{value: value, negative: negative, expiresAt: time.Now().Add(ttl)}
+}
+
+// negativeCacheTTL caps how long a failed lookup is cached for, so a vault
+// that's merely mid-incident doesn't stay "down" long after it recovers.
+func negativeCacheTTL(ttl time.Duration) time.Duration {
+	if short := ttl / 10; short > 0 {
+		return short
+	}
+	return ttl
+}
+
+// cloneCachedValue shallow-copies a map[string]interface{} result before it
+// crosses the cache boundary in either direction, so a caller that mutates
+// the map it got back (coercing types, merging in extra keys, redacting,
+// etc.) can't corrupt the entry for every other lookup sharing that cache
+// key. Other result types (a plain secret string, a *azkeys.JSONWebKey) pass
+// through unchanged.
+func cloneCachedValue(value interface{}) interface{} {
+	m, ok := value.(map[string]interface{})
+	if !ok {
+		return value
+	}
+
+	clone := make(map[string]interface{}, len(m))
+	for k, v := range m {
+		clone[k] = v
+	}
+	return clone
+}
+
+// annotationAzKVTenantID, annotationAzKVClientID and annotationAzKVAuthMode
+// let a single AVP instance pull secrets from vaults spread across tenants
+// and subscriptions by selecting which identity CredentialProvider resolves
+// for a given lookup.
+const (
+	annotationAzKVTenantID = "avp_azkv_tenant_id"
+	annotationAzKVClientID = "avp_azkv_client_id"
+	annotationAzKVAuthMode = "avp_azkv_auth_mode"
+)
+
+// annotationAzKVNamePrefix, annotationAzKVTags, annotationAzKVIncludeDisabled
+// and annotationAzKVKeyFromTag let a GetSecrets listing narrow which secrets
+// in a vault are fetched, and how the resulting map is keyed, instead of
+// always enumerating and keying by the full secret name.
+const (
+	annotationAzKVNamePrefix      = "avp_azkv_name_prefix"
+	annotationAzKVTags            = "avp_azkv_tags"
+	annotationAzKVIncludeDisabled = "avp_azkv_include_disabled"
+	annotationAzKVKeyFromTag      = "avp_azkv_key_from_tag"
+)
+
+// azKVFilter narrows a GetSecrets listing down to the secrets that match,
+// and optionally rekeys the result by a tag value rather than by name.
+type azKVFilter struct {
+	namePrefix      string
+	tags            map[string]string
+	includeDisabled bool
+	keyFromTag      string
+}
+
+// parseAzKVFilter reads the avp_azkv_name_prefix/tags/include_disabled/
+// key_from_tag annotations into an azKVFilter. avp_azkv_tags is a
+// comma-separated list of key=value pairs, all of which must match.
+func parseAzKVFilter(annotations map[string]string) azKVFilter {
+	f := azKVFilter{namePrefix: annotations[annotationAzKVNamePrefix], keyFromTag: annotations[annotationAzKVKeyFromTag]}
+
+	if includeDisabled, err := strconv.ParseBool(annotations[annotationAzKVIncludeDisabled]); err == nil {
+		f.includeDisabled = includeDisabled
+	}
+
+	if raw, ok := annotations[annotationAzKVTags]; ok && raw != "" {
+		f.tags = make(map[string]string)
+		for _, pair := range strings.Split(raw, ",") {
+			key, value, _ := strings.Cut(pair, "=")
+			f.tags[strings.TrimSpace(key)] = strings.TrimSpace(value)
+		}
+	}
+
+	return f
+}
+
+// matches reports whether a secret with the given name, enabled state and
+// tags should be included in a filtered listing.
+func (f azKVFilter) matches(name string, enabled bool, tags map[string]*string) bool {
+	if !enabled && !f.includeDisabled {
+		return false
+	}
+	if f.namePrefix != "" && !strings.HasPrefix(name, f.namePrefix) {
+		return false
+	}
+	for key, value := range f.tags {
+		got, ok := tags[key]
+		if !ok || got == nil || *got != value {
+			return false
+		}
+	}
+	return true
+}
+
+// CredentialProvider resolves the azcore.TokenCredential to authenticate a
+// Key Vault lookup with, given the tenant, client ID and auth mode taken from
+// annotations. Implementations are expected to cache credentials rather than
+// mint a fresh one per call.
+type CredentialProvider interface {
+	GetCredential(tenantID, clientID, authMode string, cloudCfg cloud.Configuration) (azcore.TokenCredential, error)
+}
+
+type credentialKey struct {
+	tenantID    string
+	clientID    string
+	authMode    string
+	cloudConfig string // cloud.Configuration isn't comparable; its AAD authority host is
+}
+
+// cachingCredentialProvider mints azidentity credentials for non-default auth
+// modes on demand and caches them by (tenant, clientID, mode, AAD authority
+// host), so repeated
+// template renders against the same identity don't re-authenticate every time.
+type cachingCredentialProvider struct {
+	defaultCredential azcore.TokenCredential
+
+	mu    sync.Mutex
+	cache map[credentialKey]azcore.TokenCredential
+}
+
+func newCachingCredentialProvider(defaultCredential azcore.TokenCredential) *cachingCredentialProvider {
+	return &cachingCredentialProvider{
+		defaultCredential: defaultCredential,
+		cache:             make(map[credentialKey]azcore.TokenCredential),
+	}
+}
+
+// GetCredential returns the process-wide default credential for "", "default"
+// or an unset auth mode, otherwise mints (and caches) a credential for the
+// requested mode. cloudCfg is threaded through to the minted credential so it
+// authenticates against the same AAD authority as the vault it's for.
+func (p *cachingCredentialProvider) GetCredential(tenantID, clientID, authMode string, cloudCfg cloud.Configuration) (azcore.TokenCredential, error) {
+	if authMode == "" || authMode == "default" {
+		return p.defaultCredential, nil
+	}
+
+	key := credentialKey{tenantID: tenantID, clientID: clientID, authMode: authMode, cloudConfig: cloudCfg.ActiveDirectoryAuthorityHost}
+
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	if cred, ok := p.cache[key]; ok {
+		return cred, nil
+	}
+
+	cred, err := newAzureCredential(tenantID, clientID, authMode, cloudCfg)
+	if err != nil {
+		return nil, err
+	}
+
+	p.cache[key] = cred
+	return cred, nil
+}
+
+// newAzureCredential mints a credential for one of the avp_azkv_auth_mode
+// values. "spn" reads its client secret from AVP_AZKV_CLIENT_SECRET, since
+// annotations aren't an appropriate place for secret material. cloudCfg is
+// passed to the workload, managed and spn credentials' ClientOptions so they
+// target the same AAD authority as the vault, not just the default public
+// cloud. The cli credential is not given cloudCfg.
+func newAzureCredential(tenantID, clientID, authMode string, cloudCfg cloud.Configuration) (azcore.TokenCredential, error) {
+	clientOptions := azcore.ClientOptions{Cloud: cloudCfg}
+	switch authMode {
+	case "workload":
+		return azidentity.NewWorkloadIdentityCredential(&azidentity.WorkloadIdentityCredentialOptions{
+			ClientOptions: clientOptions,
+			TenantID:      tenantID,
+			ClientID:      clientID,
+		})
+	case "managed":
+		opts := &azidentity.ManagedIdentityCredentialOptions{ClientOptions: clientOptions}
+		if clientID != "" {
+			opts.ID = azidentity.ClientID(clientID)
+		}
+		return azidentity.NewManagedIdentityCredential(opts)
+	case "cli":
+		return azidentity.NewAzureCLICredential(&azidentity.AzureCLICredentialOptions{TenantID: tenantID})
+	case "spn":
+		return azidentity.NewClientSecretCredential(tenantID, clientID, os.Getenv("AVP_AZKV_CLIENT_SECRET"), &azidentity.ClientSecretCredentialOptions{ClientOptions: clientOptions})
+	default:
+		return nil, fmt.Errorf("unknown %s %q", annotationAzKVAuthMode, authMode)
+	}
 }
 
 type AzSecretsClient interface {
@@ -23,109 +423,454 @@ type AzSecretsClient interface {
 	NewListSecretPropertiesPager(options *azsecrets.ListSecretPropertiesOptions) *runtime.Pager[azsecrets.ListSecretPropertiesResponse]
 }
 
+// AzCertificatesClient mirrors AzSecretsClient for the certificates API, so
+// certificate-backed paths can be mocked the same way as secrets in tests.
+type AzCertificatesClient interface {
+	GetCertificate(ctx context.Context, name string, version string, options *azcertificates.GetCertificateOptions) (azcertificates.GetCertificateResponse, error)
+	NewListCertificatePropertiesPager(options *azcertificates.ListCertificatePropertiesOptions) *runtime.Pager[azcertificates.ListCertificatePropertiesResponse]
+}
+
+// AzKeysClient mirrors AzSecretsClient for the keys API.
+type AzKeysClient interface {
+	GetKey(ctx context.Context, name string, version string, options *azkeys.GetKeyOptions) (azkeys.GetKeyResponse, error)
+	NewListKeyPropertiesPager(options *azkeys.ListKeyPropertiesOptions) *runtime.Pager[azkeys.ListKeyPropertiesResponse]
+}
+
 // NewAzureKeyVaultBackend initializes a new Azure Key Vault backend
 func NewAzureKeyVaultBackend(credential azcore.TokenCredential, clientBuilder func(vaultURL string, credential azcore.TokenCredential, options *azsecrets.ClientOptions) (*azsecrets.Client, error)) *AzureKeyVault {
 	return &AzureKeyVault{
-		Credential: credential,
+		Credential:         credential,
+		CredentialProvider: newCachingCredentialProvider(credential),
+		ResultCache:        newInMemoryResultCache(),
 		ClientBuilder: func(vaultURL string, credential azcore.TokenCredential, options *azsecrets.ClientOptions) (AzSecretsClient, error) {
 			return clientBuilder(vaultURL, credential, options)
 		},
+		CertificatesClientBuilder: func(vaultURL string, credential azcore.TokenCredential, options *azcertificates.ClientOptions) (AzCertificatesClient, error) {
+			return azcertificates.NewClient(vaultURL, credential, options)
+		},
+		KeysClientBuilder: func(vaultURL string, credential azcore.TokenCredential, options *azkeys.ClientOptions) (AzKeysClient, error) {
+			return azkeys.NewClient(vaultURL, credential, options)
+		},
 	}
 }
 
-// Login does nothing as a "login" is handled on the instantiation of the Azure SDK
+// Login pre-warms and validates the default credential by requesting a Key
+// Vault token up front, rather than leaving the first failure to surface on
+// whatever template happens to render first. Per-tenant and per-mode
+// credentials selected via annotations are resolved lazily by
+// CredentialProvider instead, since which ones are needed isn't known yet.
 func (a *AzureKeyVault) Login() error {
-	return nil
+	if a.Credential == nil {
+		return nil
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), azKVTimeout())
+	defer cancel()
+
+	_, err := a.Credential.GetToken(ctx, policy.TokenRequestOptions{Scopes: []string{defaultVaultScope()}})
+	return err
+}
+
+// resolveCredential picks the credential to authenticate a lookup with,
+// based on the avp_azkv_tenant_id/client_id/auth_mode annotations. cloudCfg
+// is passed through so a freshly minted credential targets the same AAD
+// authority as the vault being looked up.
+func (a *AzureKeyVault) resolveCredential(annotations map[string]string, cloudCfg cloud.Configuration) (azcore.TokenCredential, error) {
+	if a.CredentialProvider == nil {
+		return a.Credential, nil
+	}
+	return a.CredentialProvider.GetCredential(annotations[annotationAzKVTenantID], annotations[annotationAzKVClientID], annotations[annotationAzKVAuthMode], cloudCfg)
+}
+
+// splitPathAndKind takes the kind from the avp_azkv_kind annotation when it is
+// set, leaving the path untouched; otherwise it pulls a trailing /certificates
+// or /keys segment off of a Key Vault path, and defaults to plain secrets.
+func splitPathAndKind(path string, annotations map[string]string) (string, azKVKind) {
+	if kind, ok := annotations[annotationAzKVKind]; ok {
+		return path, azKVKind(kind)
+	}
+
+	switch {
+	case strings.HasSuffix(path, "/certificates"):
+		return strings.TrimSuffix(path, "/certificates"), azKVKindCertificate
+	case strings.HasSuffix(path, "/keys"):
+		return strings.TrimSuffix(path, "/keys"), azKVKindKey
+	default:
+		return path, azKVKindSecret
+	}
 }
 
 // GetSecrets gets secrets from Azure Key Vault and returns the formatted data
-func (a *AzureKeyVault) GetSecrets(kvpath string, version string, _ map[string]string) (map[string]interface{}, error) {
-	// Check for the cloud environment variable
-	cloud := os.Getenv("AVP_AZ_CLOUD_NAME")
-	var vaultURL string
+func (a *AzureKeyVault) GetSecrets(kvpath string, version string, annotations map[string]string) (map[string]interface{}, error) {
+	kvpath, kind := splitPathAndKind(kvpath, annotations)
 
-	if cloud == "azurechina" {
-		vaultURL = fmt.Sprintf("https://%s.vault.azure.cn", kvpath)
-	} else {
-		vaultURL = fmt.Sprintf("https://%s.vault.azure.net", kvpath)
+	vaultURL, cloudCfg, err := resolveVaultURL(kvpath)
+	if err != nil {
+		return nil, err
+	}
+
+	filter := parseAzKVFilter(annotations)
+
+	ttl := azKVCacheTTL()
+	// Include the identity annotations in the key: Key Vault enforces secret-level
+	// RBAC, so results fetched under one identity must not be served to a lookup
+	// that asked for a different (possibly more restricted) one.
+	cacheKey := fmt.Sprintf("secrets|%s|%s|%s|%s|%s|%s|%s|%s|%t|%s", kind, vaultURL, version,
+		annotations[annotationAzKVTenantID], annotations[annotationAzKVClientID], annotations[annotationAzKVAuthMode],
+		filter.namePrefix, annotations[annotationAzKVTags], filter.includeDisabled, filter.keyFromTag)
+	if ttl > 0 && a.ResultCache != nil {
+		if value, negative, hit := a.ResultCache.Get(cacheKey); hit {
+			if negative {
+				return nil, value.(error)
+			}
+			return cloneCachedValue(value).(map[string]interface{}), nil
+		}
 	}
 
-	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	credential, err := a.resolveCredential(annotations, cloudCfg)
+	if err != nil {
+		return nil, err
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), azKVListTimeout())
 	defer cancel()
 
+	var data map[string]interface{}
+	switch kind {
+	case azKVKindCertificate:
+		data, err = a.getCertificates(ctx, vaultURL, cloudCfg, credential, version, filter)
+	case azKVKindKey:
+		data, err = a.getKeys(ctx, vaultURL, cloudCfg, credential, version, filter)
+	default:
+		data, err = a.getSecrets(ctx, vaultURL, cloudCfg, credential, version, filter)
+	}
+
+	if ttl > 0 && a.ResultCache != nil {
+		if err != nil {
+			a.ResultCache.Set(cacheKey, err, true, negativeCacheTTL(ttl))
+		} else {
+			a.ResultCache.Set(cacheKey, cloneCachedValue(data), false, ttl)
+		}
+	}
+
+	return data, err
+}
+
+// getSecrets fans GetSecret calls for every secret matching filter out across
+// a bounded worker pool instead of walking the vault one secret at a time.
+// The shared context is cancelled on the first hard error so in-flight and
+// not-yet-started fetches stop promptly. The Key Vault list API doesn't
+// expose name/tag filtering, so filter is applied client-side while walking
+// the pager, before GetSecret is ever called for a non-matching secret.
+func (a *AzureKeyVault) getSecrets(ctx context.Context, vaultURL string, cloudCfg cloud.Configuration, credential azcore.TokenCredential, version string, filter azKVFilter) (map[string]interface{}, error) {
 	verboseOptionalVersion("Azure Key Vault list all secrets from vault %s", version, vaultURL)
 
-	client, err := a.ClientBuilder(vaultURL, a.Credential, nil)
+	client, err := a.ClientBuilder(vaultURL, credential, &azsecrets.ClientOptions{ClientOptions: azcore.ClientOptions{Cloud: cloudCfg}})
 	if err != nil {
 		return nil, err
 	}
 
-	data := make(map[string]interface{})
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	var (
+		mu       sync.Mutex
+		wg       sync.WaitGroup
+		sem      = make(chan struct{}, azKVConcurrency())
+		data     = make(map[string]interface{})
+		firstErr error
+		seenKeys map[string]string // key (e.g. tag value) -> secret name, to catch avp_azkv_key_from_tag collisions
+	)
+	if filter.keyFromTag != "" {
+		seenKeys = make(map[string]string)
+	}
+
+	fail := func(err error) {
+		mu.Lock()
+		defer mu.Unlock()
+		if firstErr == nil {
+			firstErr = err
+			cancel()
+		}
+	}
+
+	fetch := func(name, key string, versionMatches bool) {
+		defer wg.Done()
+		defer func() { <-sem }()
+
+		callCtx, callCancel := context.WithTimeout(ctx, azKVTimeout())
+		defer callCancel()
+
+		verboseOptionalVersion("Azure Key Vault getting secret %s from vault %s", version, name, vaultURL)
+		secret, err := client.GetSecret(callCtx, name, version, nil)
+		if err != nil {
+			if softFailOnVersionMismatch(version, versionMatches) {
+				utils.VerboseToStdErr("Azure Key Vault get versioned secret not found %s", err)
+				return
+			}
+			fail(err)
+			return
+		}
+		utils.VerboseToStdErr("Azure Key Vault get secret response %v", secret)
+
+		mu.Lock()
+		data[key] = *secret.Value
+		mu.Unlock()
+	}
 
 	pager := client.NewListSecretPropertiesPager(nil)
+pageLoop:
 	for pager.More() {
 		page, err := pager.NextPage(ctx)
 		if err != nil {
-			return nil, err
+			fail(err)
+			break
 		}
 		for _, secretVersion := range page.Value {
-			if !*secretVersion.Attributes.Enabled {
+			name := secretVersion.ID.Name()
+			if !filter.matches(name, *secretVersion.Attributes.Enabled, secretVersion.Tags) {
 				continue
 			}
-			name := secretVersion.ID.Name()
-			if version == "" || secretVersion.ID.Version() == version {
-				verboseOptionalVersion("Azure Key Vault getting secret %s from vault %s", version, name, vaultURL)
-				secret, err := client.GetSecret(ctx, name, version, nil)
-				if err != nil {
-					return nil, err
+			versionMatches := version == "" || secretVersion.ID.Version() == version
+
+			key := name
+			if filter.keyFromTag != "" {
+				tagValue := secretVersion.Tags[filter.keyFromTag]
+				if tagValue == nil {
+					continue
 				}
-				utils.VerboseToStdErr("Azure Key Vault get secret response %v", secret)
-				data[name] = *secret.Value
-			} else {
-				verboseOptionalVersion("Azure Key Vault getting secret %s from vault %s", version, name, vaultURL)
-				secret, err := client.GetSecret(ctx, name, version, nil)
-				if err != nil || !*secretVersion.Attributes.Enabled {
-					utils.VerboseToStdErr("Azure Key Vault get versioned secret not found %s", err)
+				key = *tagValue
+				if existing, ok := seenKeys[key]; ok {
+					fail(fmt.Errorf("azure key vault: secrets %q and %q both resolve to key %q via %s; keys must be unique", existing, name, key, annotationAzKVKeyFromTag))
+					break pageLoop
+				}
+				seenKeys[key] = name
+			}
+
+			select {
+			case sem <- struct{}{}:
+			case <-ctx.Done():
+				break pageLoop
+			}
+			wg.Add(1)
+			go fetch(name, key, versionMatches)
+		}
+	}
+
+	wg.Wait()
+
+	if firstErr != nil {
+		return nil, firstErr
+	}
+	return data, nil
+}
+
+func (a *AzureKeyVault) getCertificates(ctx context.Context, vaultURL string, cloudCfg cloud.Configuration, credential azcore.TokenCredential, version string, filter azKVFilter) (map[string]interface{}, error) {
+	verboseOptionalVersion("Azure Key Vault list all certificates from vault %s", version, vaultURL)
+
+	client, err := a.CertificatesClientBuilder(vaultURL, credential, &azcertificates.ClientOptions{ClientOptions: azcore.ClientOptions{Cloud: cloudCfg}})
+	if err != nil {
+		return nil, err
+	}
+
+	data := make(map[string]interface{})
+	var seenKeys map[string]string
+	if filter.keyFromTag != "" {
+		seenKeys = make(map[string]string)
+	}
+
+	pager := client.NewListCertificatePropertiesPager(nil)
+	for pager.More() {
+		page, err := pager.NextPage(ctx)
+		if err != nil {
+			return nil, err
+		}
+		for _, certProperties := range page.Value {
+			name := certProperties.ID.Name()
+			if !filter.matches(name, *certProperties.Attributes.Enabled, certProperties.Tags) {
+				continue
+			}
+			key, skip, err := resolveFilterKey(filter, name, certProperties.Tags, seenKeys)
+			if err != nil {
+				return nil, err
+			}
+			if skip {
+				continue
+			}
+
+			versionMatches := version == "" || certProperties.ID.Version() == version
+			cert, err := a.getCertificate(ctx, client, vaultURL, cloudCfg, credential, name, version)
+			if err != nil {
+				if softFailOnVersionMismatch(version, versionMatches) {
+					utils.VerboseToStdErr("Azure Key Vault get versioned certificate not found %s", err)
 					continue
 				}
-				utils.VerboseToStdErr("Azure Key Vault get versioned secret response %v", secret)
-				data[name] = *secret.Value
+				return nil, err
 			}
+			data[key] = cert
 		}
 	}
 	return data, nil
 }
 
+func (a *AzureKeyVault) getKeys(ctx context.Context, vaultURL string, cloudCfg cloud.Configuration, credential azcore.TokenCredential, version string, filter azKVFilter) (map[string]interface{}, error) {
+	verboseOptionalVersion("Azure Key Vault list all keys from vault %s", version, vaultURL)
+
+	client, err := a.KeysClientBuilder(vaultURL, credential, &azkeys.ClientOptions{ClientOptions: azcore.ClientOptions{Cloud: cloudCfg}})
+	if err != nil {
+		return nil, err
+	}
+
+	data := make(map[string]interface{})
+	var seenKeys map[string]string
+	if filter.keyFromTag != "" {
+		seenKeys = make(map[string]string)
+	}
+
+	pager := client.NewListKeyPropertiesPager(nil)
+	for pager.More() {
+		page, err := pager.NextPage(ctx)
+		if err != nil {
+			return nil, err
+		}
+		for _, keyProperties := range page.Value {
+			name := keyProperties.KID.Name()
+			if !filter.matches(name, *keyProperties.Attributes.Enabled, keyProperties.Tags) {
+				continue
+			}
+			key, skip, err := resolveFilterKey(filter, name, keyProperties.Tags, seenKeys)
+			if err != nil {
+				return nil, err
+			}
+			if skip {
+				continue
+			}
+
+			versionMatches := version == "" || keyProperties.KID.Version() == version
+			verboseOptionalVersion("Azure Key Vault getting key %s from vault %s", version, name, vaultURL)
+			keyResp, err := client.GetKey(ctx, name, version, nil)
+			if err != nil {
+				if softFailOnVersionMismatch(version, versionMatches) {
+					utils.VerboseToStdErr("Azure Key Vault get versioned key not found %s", err)
+					continue
+				}
+				return nil, err
+			}
+			utils.VerboseToStdErr("Azure Key Vault get key response %v", keyResp)
+			data[key] = keyResp.Key
+		}
+	}
+	return data, nil
+}
+
+// getCertificate resolves a single certificate to its PEM-encoded public
+// material. When the certificate's policy allows export, the private key and
+// chain live alongside it in the secrets API under the same name, so we best
+// effort pull that too rather than failing the whole lookup.
+func (a *AzureKeyVault) getCertificate(ctx context.Context, client AzCertificatesClient, vaultURL string, cloudCfg cloud.Configuration, credential azcore.TokenCredential, name, version string) (map[string]interface{}, error) {
+	verboseOptionalVersion("Azure Key Vault getting certificate %s from vault %s", version, name, vaultURL)
+
+	cert, err := client.GetCertificate(ctx, name, version, nil)
+	if err != nil {
+		return nil, err
+	}
+	utils.VerboseToStdErr("Azure Key Vault get certificate response %v", cert)
+
+	result := map[string]interface{}{
+		"certificate": string(pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: cert.CER})),
+	}
+
+	if secretsClient, err := a.ClientBuilder(vaultURL, credential, &azsecrets.ClientOptions{ClientOptions: azcore.ClientOptions{Cloud: cloudCfg}}); err == nil {
+		if secret, err := secretsClient.GetSecret(ctx, name, version, nil); err == nil {
+			result["key"] = *secret.Value
+		} else {
+			utils.VerboseToStdErr("Azure Key Vault certificate %s has no exportable private key: %s", name, err)
+		}
+	}
+
+	return result, nil
+}
+
 // GetIndividualSecret will get the specific secret from the SM backend
 func (a *AzureKeyVault) GetIndividualSecret(kvpath, secret, version string, annotations map[string]string) (interface{}, error) {
-	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	kvpath, kind := splitPathAndKind(kvpath, annotations)
+
+	ctx, cancel := context.WithTimeout(context.Background(), azKVTimeout())
 	defer cancel()
 
 	verboseOptionalVersion("Azure Key Vault getting individual secret %s from vault %s", version, secret, kvpath)
 
-	// Check for the cloud environment variable
-	cloud := os.Getenv("AVP_AZ_CLOUD_NAME")
-	var vaultURL string
+	vaultURL, cloudCfg, err := resolveVaultURL(kvpath)
+	if err != nil {
+		return nil, err
+	}
 
-	if cloud == "azurechina" {
-		vaultURL = fmt.Sprintf("https://%s.vault.azure.cn", kvpath)
-	} else {
-		vaultURL = fmt.Sprintf("https://%s.vault.azure.net", kvpath)
+	ttl := azKVCacheTTL()
+	// See GetSecrets: the identity annotations are part of the key so a result
+	// fetched under one identity isn't served to a lookup asking for another.
+	cacheKey := fmt.Sprintf("secret|%s|%s|%s|%s|%s|%s|%s", kind, vaultURL, secret, version,
+		annotations[annotationAzKVTenantID], annotations[annotationAzKVClientID], annotations[annotationAzKVAuthMode])
+	if ttl > 0 && a.ResultCache != nil {
+		if value, negative, hit := a.ResultCache.Get(cacheKey); hit {
+			if negative {
+				return nil, value.(error)
+			}
+			return cloneCachedValue(value), nil
+		}
 	}
 
-	client, err := a.ClientBuilder(vaultURL, a.Credential, nil)
-	if err != nil {
-		return nil, err
+	result, err := a.getIndividualSecret(ctx, vaultURL, cloudCfg, kind, secret, version, annotations)
+
+	if ttl > 0 && a.ResultCache != nil {
+		if err != nil {
+			a.ResultCache.Set(cacheKey, err, true, negativeCacheTTL(ttl))
+		} else {
+			a.ResultCache.Set(cacheKey, cloneCachedValue(result), false, ttl)
+		}
 	}
 
-	data, err := client.GetSecret(ctx, secret, version, nil)
+	return result, err
+}
+
+func (a *AzureKeyVault) getIndividualSecret(ctx context.Context, vaultURL string, cloudCfg cloud.Configuration, kind azKVKind, secret, version string, annotations map[string]string) (interface{}, error) {
+	credential, err := a.resolveCredential(annotations, cloudCfg)
 	if err != nil {
 		return nil, err
 	}
 
-	utils.VerboseToStdErr("Azure Key Vault get individual secret response %v", data)
+	switch kind {
+	case azKVKindCertificate:
+		client, err := a.CertificatesClientBuilder(vaultURL, credential, &azcertificates.ClientOptions{ClientOptions: azcore.ClientOptions{Cloud: cloudCfg}})
+		if err != nil {
+			return nil, err
+		}
+		return a.getCertificate(ctx, client, vaultURL, cloudCfg, credential, secret, version)
+	case azKVKindKey:
+		client, err := a.KeysClientBuilder(vaultURL, credential, &azkeys.ClientOptions{ClientOptions: azcore.ClientOptions{Cloud: cloudCfg}})
+		if err != nil {
+			return nil, err
+		}
+		key, err := client.GetKey(ctx, secret, version, nil)
+		if err != nil {
+			return nil, err
+		}
+		utils.VerboseToStdErr("Azure Key Vault get individual key response %v", key)
+		return key.Key, nil
+	default:
+		client, err := a.ClientBuilder(vaultURL, credential, &azsecrets.ClientOptions{ClientOptions: azcore.ClientOptions{Cloud: cloudCfg}})
+		if err != nil {
+			return nil, err
+		}
+
+		data, err := client.GetSecret(ctx, secret, version, nil)
+		if err != nil {
+			return nil, err
+		}
+
+		utils.VerboseToStdErr("Azure Key Vault get individual secret response %v", data)
 
-	return *data.Value, nil
+		return *data.Value, nil
+	}
 }
 
 func verboseOptionalVersion(format string, version string, message ...interface{}) {
@@ -134,4 +879,4 @@ func verboseOptionalVersion(format string, version string, message ...interface{
 	} else {
 		utils.VerboseToStdErr(format+" at version %s", append(message, version)...)
 	}
-}
\ No newline at end of file
+}
@@ -5,10 +5,15 @@ import (
 	"errors"
 	"os"
 	"reflect"
+	"sync/atomic"
 	"testing"
+	"time"
 
 	"github.com/Azure/azure-sdk-for-go/sdk/azcore"
+	"github.com/Azure/azure-sdk-for-go/sdk/azcore/cloud"
 	"github.com/Azure/azure-sdk-for-go/sdk/azcore/runtime"
+	"github.com/Azure/azure-sdk-for-go/sdk/security/keyvault/azcertificates"
+	"github.com/Azure/azure-sdk-for-go/sdk/security/keyvault/azkeys"
 	"github.com/Azure/azure-sdk-for-go/sdk/security/keyvault/azsecrets"
 	"github.com/argoproj-labs/argocd-vault-plugin/pkg/backends"
 )
@@ -16,6 +21,11 @@ import (
 type mockClientProxy struct {
 	simulateError string
 	secretPrefix  string
+	tags          map[string]map[string]*string // secret name -> tags, for filter tests
+	listCalls     int32
+	getCalls      int32
+	inFlight      int32 // GetSecret calls currently sleeping, for concurrency assertions
+	maxInFlight   int32
 }
 
 func newMockClientProxy(simulateError, secretPrefix string) *mockClientProxy {
@@ -25,12 +35,13 @@ func newMockClientProxy(simulateError, secretPrefix string) *mockClientProxy {
 	}
 }
 
-func makeSecretProperties(id azsecrets.ID, enable bool) *azsecrets.SecretProperties {
+func makeSecretProperties(id azsecrets.ID, enable bool, tags map[string]*string) *azsecrets.SecretProperties {
 	return &azsecrets.SecretProperties{
 		ID: &id,
 		Attributes: &azsecrets.SecretAttributes{
 			Enabled: &enable,
 		},
+		Tags: tags,
 	}
 }
 
@@ -44,6 +55,7 @@ func makeResponse(id azsecrets.ID, value string, err error) (azsecrets.GetSecret
 }
 
 func (c *mockClientProxy) NewListSecretPropertiesPager(options *azsecrets.ListSecretPropertiesOptions) *runtime.Pager[azsecrets.ListSecretPropertiesResponse] {
+	atomic.AddInt32(&c.listCalls, 1)
 	var pageCount = 0
 	pager := runtime.NewPager(runtime.PagingHandler[azsecrets.ListSecretPropertiesResponse]{
 		More: func(current azsecrets.ListSecretPropertiesResponse) bool {
@@ -55,9 +67,9 @@ func (c *mockClientProxy) NewListSecretPropertiesPager(options *azsecrets.ListSe
 			if c.simulateError == "fetch_error" {
 				return azsecrets.ListSecretPropertiesResponse{}, errors.New("fetch error")
 			}
-			a = append(a, makeSecretProperties(azsecrets.ID(c.secretPrefix+"simple/v2"), true))
-			a = append(a, makeSecretProperties(azsecrets.ID(c.secretPrefix+"second/v2"), true))
-			a = append(a, makeSecretProperties(azsecrets.ID(c.secretPrefix+"disabled/v2"), false))
+			a = append(a, makeSecretProperties(azsecrets.ID(c.secretPrefix+"simple/v2"), true, c.tags["simple"]))
+			a = append(a, makeSecretProperties(azsecrets.ID(c.secretPrefix+"second/v2"), true, c.tags["second"]))
+			a = append(a, makeSecretProperties(azsecrets.ID(c.secretPrefix+"disabled/v2"), false, c.tags["disabled"]))
 			return azsecrets.ListSecretPropertiesResponse{
 				SecretPropertiesListResult: azsecrets.SecretPropertiesListResult{
 					Value: a,
@@ -69,22 +81,421 @@ func (c *mockClientProxy) NewListSecretPropertiesPager(options *azsecrets.ListSe
 }
 
 func (c *mockClientProxy) GetSecret(ctx context.Context, name string, version string, options *azsecrets.GetSecretOptions) (azsecrets.GetSecretResponse, error) {
+	atomic.AddInt32(&c.getCalls, 1)
+	if c.simulateError == "slow" {
+		inFlight := atomic.AddInt32(&c.inFlight, 1)
+		for {
+			max := atomic.LoadInt32(&c.maxInFlight)
+			if inFlight <= max || atomic.CompareAndSwapInt32(&c.maxInFlight, max, inFlight) {
+				break
+			}
+		}
+		time.Sleep(20 * time.Millisecond)
+		atomic.AddInt32(&c.inFlight, -1)
+	}
+	if c.simulateError == "get_error" && name == "second" {
+		return makeResponse(azsecrets.ID(""), "", errors.New("transient get error"))
+	}
 	if name == "simple" && (version == "" || version == "v1") {
 		return makeResponse(azsecrets.ID(c.secretPrefix+"simple/v1"), "a_value_v1", nil)
 	} else if name == "simple" && version == "v2" {
 		return makeResponse(azsecrets.ID(c.secretPrefix+"simple/v2"), "a_value_v2", nil)
 	} else if name == "second" && (version == "" || version == "v2") {
 		return makeResponse(azsecrets.ID(c.secretPrefix+"second/v2"), "a_second_value_v2", nil)
+	} else if name == "disabled" && (version == "" || version == "v2") {
+		return makeResponse(azsecrets.ID(c.secretPrefix+"disabled/v2"), "a_disabled_value_v2", nil)
 	}
 	return makeResponse(azsecrets.ID(""), "", errors.New("secret not found"))
 }
 
+type mockCertificatesClientProxy struct{}
+
+func (c *mockCertificatesClientProxy) GetCertificate(ctx context.Context, name string, version string, options *azcertificates.GetCertificateOptions) (azcertificates.GetCertificateResponse, error) {
+	if name != "mycert" && name != "hiddencert" {
+		return azcertificates.GetCertificateResponse{}, errors.New("certificate not found")
+	}
+	return azcertificates.GetCertificateResponse{
+		Certificate: azcertificates.Certificate{
+			CER: []byte("fake-der-bytes"),
+		},
+	}, nil
+}
+
+func (c *mockCertificatesClientProxy) NewListCertificatePropertiesPager(options *azcertificates.ListCertificatePropertiesOptions) *runtime.Pager[azcertificates.ListCertificatePropertiesResponse] {
+	var pageCount = 0
+	return runtime.NewPager(runtime.PagingHandler[azcertificates.ListCertificatePropertiesResponse]{
+		More: func(current azcertificates.ListCertificatePropertiesResponse) bool {
+			return pageCount == 0
+		},
+		Fetcher: func(ctx context.Context, current *azcertificates.ListCertificatePropertiesResponse) (azcertificates.ListCertificatePropertiesResponse, error) {
+			pageCount++
+			enabled, disabled := true, false
+			visibleID := azcertificates.ID("https://myvaultname.vault.azure.net/certificates/mycert/v1")
+			hiddenID := azcertificates.ID("https://myvaultname.vault.azure.net/certificates/hiddencert/v1")
+			return azcertificates.ListCertificatePropertiesResponse{
+				CertificatePropertiesListResult: azcertificates.CertificatePropertiesListResult{
+					Value: []*azcertificates.CertificateProperties{
+						{ID: &visibleID, Attributes: &azcertificates.CertificateAttributes{Enabled: &enabled}},
+						{ID: &hiddenID, Attributes: &azcertificates.CertificateAttributes{Enabled: &disabled}},
+					},
+				},
+			}, nil
+		},
+	})
+}
+
+type mockKeysClientProxy struct{}
+
+func (c *mockKeysClientProxy) GetKey(ctx context.Context, name string, version string, options *azkeys.GetKeyOptions) (azkeys.GetKeyResponse, error) {
+	if name != "mykey" && name != "hiddenkey" {
+		return azkeys.GetKeyResponse{}, errors.New("key not found")
+	}
+	return azkeys.GetKeyResponse{
+		KeyBundle: azkeys.KeyBundle{
+			Key: &azkeys.JSONWebKey{KID: toPtr(azkeys.ID(name))},
+		},
+	}, nil
+}
+
+func (c *mockKeysClientProxy) NewListKeyPropertiesPager(options *azkeys.ListKeyPropertiesOptions) *runtime.Pager[azkeys.ListKeyPropertiesResponse] {
+	var pageCount = 0
+	return runtime.NewPager(runtime.PagingHandler[azkeys.ListKeyPropertiesResponse]{
+		More: func(current azkeys.ListKeyPropertiesResponse) bool {
+			return pageCount == 0
+		},
+		Fetcher: func(ctx context.Context, current *azkeys.ListKeyPropertiesResponse) (azkeys.ListKeyPropertiesResponse, error) {
+			pageCount++
+			enabled, disabled := true, false
+			visibleID := azkeys.ID("https://myvaultname.vault.azure.net/keys/mykey/v1")
+			hiddenID := azkeys.ID("https://myvaultname.vault.azure.net/keys/hiddenkey/v1")
+			return azkeys.ListKeyPropertiesResponse{
+				KeyPropertiesListResult: azkeys.KeyPropertiesListResult{
+					Value: []*azkeys.KeyProperties{
+						{KID: &visibleID, Attributes: &azkeys.KeyAttributes{Enabled: &enabled}},
+						{KID: &hiddenID, Attributes: &azkeys.KeyAttributes{Enabled: &disabled}},
+					},
+				},
+			}, nil
+		},
+	})
+}
+
+func toPtr[T any](v T) *T {
+	return &v
+}
+
+type testResultCache struct {
+	entries map[string]struct {
+		value    interface{}
+		negative bool
+		ttl      time.Duration
+	}
+	setCalls int32
+}
+
+func newTestResultCache() *testResultCache {
+	return &testResultCache{entries: make(map[string]struct {
+		value    interface{}
+		negative bool
+		ttl      time.Duration
+	})}
+}
+
+func (c *testResultCache) Get(key string) (interface{}, bool, bool) {
+	entry, ok := c.entries[key]
+	if !ok {
+		return nil, false, false
+	}
+	return entry.value, entry.negative, true
+}
+
+func (c *testResultCache) Set(key string, value interface{}, negative bool, ttl time.Duration) {
+	atomic.AddInt32(&c.setCalls, 1)
+	c.entries[key] = struct {
+		value    interface{}
+		negative bool
+		ttl      time.Duration
+	}{value: value, negative: negative, ttl: ttl}
+}
+
 func newAzureKeyVaultBackendMock(simulateError, secretPrefix string) *backends.AzureKeyVault {
 	return &backends.AzureKeyVault{
 		Credential: nil,
 		ClientBuilder: func(vaultURL string, credential azcore.TokenCredential, options *azsecrets.ClientOptions) (backends.AzSecretsClient, error) {
 			return newMockClientProxy(simulateError, secretPrefix), nil
 		},
+		CertificatesClientBuilder: func(vaultURL string, credential azcore.TokenCredential, options *azcertificates.ClientOptions) (backends.AzCertificatesClient, error) {
+			return &mockCertificatesClientProxy{}, nil
+		},
+		KeysClientBuilder: func(vaultURL string, credential azcore.TokenCredential, options *azkeys.ClientOptions) (backends.AzKeysClient, error) {
+			return &mockKeysClientProxy{}, nil
+		},
+	}
+}
+
+type stubCredentialProvider struct {
+	gotTenantID, gotClientID, gotAuthMode string
+	err                                   error
+}
+
+func (p *stubCredentialProvider) GetCredential(tenantID, clientID, authMode string, cloudCfg cloud.Configuration) (azcore.TokenCredential, error) {
+	p.gotTenantID, p.gotClientID, p.gotAuthMode = tenantID, clientID, authMode
+	if p.err != nil {
+		return nil, p.err
+	}
+	return nil, nil
+}
+
+func TestAzGetSecretsUsesCredentialProviderFromAnnotations(t *testing.T) {
+	provider := &stubCredentialProvider{}
+	keyVault := newAzureKeyVaultBackendMock("", "https://myvaultname.vault.azure.net/secrets/")
+	keyVault.CredentialProvider = provider
+
+	annotations := map[string]string{
+		"avp_azkv_tenant_id": "tenant-1",
+		"avp_azkv_client_id": "client-1",
+		"avp_azkv_auth_mode": "workload",
+	}
+	if _, err := keyVault.GetSecrets("keyvault", "", annotations); err != nil {
+		t.Fatalf("expected 0 errors but got: %s", err)
+	}
+
+	if provider.gotTenantID != "tenant-1" || provider.gotClientID != "client-1" || provider.gotAuthMode != "workload" {
+		t.Errorf("expected provider to be called with the annotation values, got: %+v", provider)
+	}
+}
+
+func TestAzGetSecretsSurfacesCredentialProviderError(t *testing.T) {
+	provider := &stubCredentialProvider{err: errors.New("no workload identity available")}
+	keyVault := newAzureKeyVaultBackendMock("", "")
+	keyVault.CredentialProvider = provider
+
+	_, err := keyVault.GetSecrets("keyvault", "", map[string]string{"avp_azkv_auth_mode": "workload"})
+	if err == nil {
+		t.Fatal("expected an error but got none")
+	}
+}
+
+func TestAzGetSecretsCacheHitSkipsBackend(t *testing.T) {
+	os.Setenv("AVP_AZ_KV_CACHE_TTL", "1m")
+	defer os.Unsetenv("AVP_AZ_KV_CACHE_TTL")
+
+	client := newMockClientProxy("", "https://myvaultname.vault.azure.net/secrets/")
+	keyVault := &backends.AzureKeyVault{
+		ResultCache: newTestResultCache(),
+		ClientBuilder: func(vaultURL string, credential azcore.TokenCredential, options *azsecrets.ClientOptions) (backends.AzSecretsClient, error) {
+			return client, nil
+		},
+	}
+
+	expected := map[string]interface{}{
+		"simple": "a_value_v1",
+		"second": "a_second_value_v2",
+	}
+
+	for i := 0; i < 2; i++ {
+		res, err := keyVault.GetSecrets("keyvault", "", nil)
+		if err != nil {
+			t.Fatalf("call %d: expected 0 errors but got: %s", i, err)
+		}
+		if !reflect.DeepEqual(res, expected) {
+			t.Errorf("call %d: expected: %v, got: %v.", i, expected, res)
+		}
+	}
+
+	if client.listCalls != 1 {
+		t.Errorf("expected the vault to be listed once, got %d calls", client.listCalls)
+	}
+}
+
+func TestAzGetSecretsCacheKeyIncludesIdentity(t *testing.T) {
+	// Key Vault enforces secret-level RBAC, so a lookup for one identity must
+	// not be served a cache hit populated by a different identity's lookup.
+	os.Setenv("AVP_AZ_KV_CACHE_TTL", "1m")
+	defer os.Unsetenv("AVP_AZ_KV_CACHE_TTL")
+
+	client := newMockClientProxy("", "https://myvaultname.vault.azure.net/secrets/")
+	keyVault := &backends.AzureKeyVault{
+		ResultCache: newTestResultCache(),
+		ClientBuilder: func(vaultURL string, credential azcore.TokenCredential, options *azsecrets.ClientOptions) (backends.AzSecretsClient, error) {
+			return client, nil
+		},
+	}
+
+	if _, err := keyVault.GetSecrets("keyvault", "", map[string]string{"avp_azkv_auth_mode": "default"}); err != nil {
+		t.Fatalf("expected 0 errors but got: %s", err)
+	}
+	if _, err := keyVault.GetSecrets("keyvault", "", map[string]string{"avp_azkv_tenant_id": "tenant-1", "avp_azkv_auth_mode": "cli"}); err != nil {
+		t.Fatalf("expected 0 errors but got: %s", err)
+	}
+
+	if client.listCalls != 2 {
+		t.Errorf("expected a distinct cache entry per identity, got %d list calls", client.listCalls)
+	}
+}
+
+func TestAzGetSecretsNoCacheWhenTTLUnset(t *testing.T) {
+	client := newMockClientProxy("", "https://myvaultname.vault.azure.net/secrets/")
+	keyVault := &backends.AzureKeyVault{
+		ResultCache: newTestResultCache(),
+		ClientBuilder: func(vaultURL string, credential azcore.TokenCredential, options *azsecrets.ClientOptions) (backends.AzSecretsClient, error) {
+			return client, nil
+		},
+	}
+
+	for i := 0; i < 2; i++ {
+		if _, err := keyVault.GetSecrets("keyvault", "", nil); err != nil {
+			t.Fatalf("call %d: expected 0 errors but got: %s", i, err)
+		}
+	}
+
+	if client.listCalls != 2 {
+		t.Errorf("expected caching to be disabled by default, got %d list calls", client.listCalls)
+	}
+}
+
+func TestAzGetSecretsCachesNegativeLookup(t *testing.T) {
+	os.Setenv("AVP_AZ_KV_CACHE_TTL", "1m")
+	defer os.Unsetenv("AVP_AZ_KV_CACHE_TTL")
+
+	client := newMockClientProxy("fetch_error", "https://myvaultname.vault.azure.net/secrets/")
+	cache := newTestResultCache()
+	keyVault := &backends.AzureKeyVault{
+		ResultCache: cache,
+		ClientBuilder: func(vaultURL string, credential azcore.TokenCredential, options *azsecrets.ClientOptions) (backends.AzSecretsClient, error) {
+			return client, nil
+		},
+	}
+
+	var firstErr error
+	for i := 0; i < 2; i++ {
+		_, err := keyVault.GetSecrets("keyvault", "", nil)
+		if err == nil {
+			t.Fatalf("call %d: expected an error but got none", i)
+		}
+		if i == 0 {
+			firstErr = err
+		} else if err.Error() != firstErr.Error() {
+			t.Errorf("expected the cached error to be returned, got: %s", err)
+		}
+	}
+
+	if client.listCalls != 1 {
+		t.Errorf("expected the second call to short-circuit on the cached error, got %d list calls", client.listCalls)
+	}
+	if cache.setCalls != 1 {
+		t.Errorf("expected only the first call to populate the cache, got %d Set calls", cache.setCalls)
+	}
+
+	var gotTTL time.Duration
+	for _, entry := range cache.entries {
+		if !entry.negative {
+			t.Errorf("expected the cached entry to be negative")
+		}
+		gotTTL = entry.ttl
+	}
+	// Mirrors negativeCacheTTL(1m) = 1m/10, the shorter TTL negative lookups
+	// get so a vault that's merely mid-incident doesn't stay "down" long.
+	if expectedTTL := time.Minute / 10; gotTTL != expectedTTL {
+		t.Errorf("expected the negative entry to use the shortened negative-cache TTL %s, got %s", expectedTTL, gotTTL)
+	}
+}
+
+func TestAzGetSecretsFiltersByNamePrefix(t *testing.T) {
+	keyVault := newAzureKeyVaultBackendMock("", "https://myvaultname.vault.azure.net/secrets/")
+
+	res, err := keyVault.GetSecrets("keyvault", "", map[string]string{"avp_azkv_name_prefix": "sec"})
+	if err != nil {
+		t.Fatalf("expected 0 errors but got: %s", err)
+	}
+
+	expected := map[string]interface{}{"second": "a_second_value_v2"}
+	if !reflect.DeepEqual(res, expected) {
+		t.Errorf("expected: %v, got: %v", expected, res)
+	}
+}
+
+func TestAzGetSecretsFiltersByTags(t *testing.T) {
+	client := newMockClientProxy("", "https://myvaultname.vault.azure.net/secrets/")
+	client.tags = map[string]map[string]*string{
+		"simple": {"env": toPtr("prod")},
+		"second": {"env": toPtr("dev")},
+	}
+	keyVault := &backends.AzureKeyVault{
+		ClientBuilder: func(vaultURL string, credential azcore.TokenCredential, options *azsecrets.ClientOptions) (backends.AzSecretsClient, error) {
+			return client, nil
+		},
+	}
+
+	res, err := keyVault.GetSecrets("keyvault", "", map[string]string{"avp_azkv_tags": "env=prod"})
+	if err != nil {
+		t.Fatalf("expected 0 errors but got: %s", err)
+	}
+
+	expected := map[string]interface{}{"simple": "a_value_v1"}
+	if !reflect.DeepEqual(res, expected) {
+		t.Errorf("expected: %v, got: %v", expected, res)
+	}
+}
+
+func TestAzGetSecretsIncludeDisabled(t *testing.T) {
+	keyVault := newAzureKeyVaultBackendMock("", "https://myvaultname.vault.azure.net/secrets/")
+
+	res, err := keyVault.GetSecrets("keyvault", "", map[string]string{"avp_azkv_include_disabled": "true"})
+	if err != nil {
+		t.Fatalf("expected 0 errors but got: %s", err)
+	}
+
+	expected := map[string]interface{}{
+		"simple":   "a_value_v1",
+		"second":   "a_second_value_v2",
+		"disabled": "a_disabled_value_v2",
+	}
+	if !reflect.DeepEqual(res, expected) {
+		t.Errorf("expected: %v, got: %v", expected, res)
+	}
+}
+
+func TestAzGetSecretsKeyFromTag(t *testing.T) {
+	client := newMockClientProxy("", "https://myvaultname.vault.azure.net/secrets/")
+	client.tags = map[string]map[string]*string{
+		"simple": {"alias": toPtr("primary")},
+		"second": {"alias": toPtr("secondary")},
+	}
+	keyVault := &backends.AzureKeyVault{
+		ClientBuilder: func(vaultURL string, credential azcore.TokenCredential, options *azsecrets.ClientOptions) (backends.AzSecretsClient, error) {
+			return client, nil
+		},
+	}
+
+	res, err := keyVault.GetSecrets("keyvault", "", map[string]string{"avp_azkv_key_from_tag": "alias"})
+	if err != nil {
+		t.Fatalf("expected 0 errors but got: %s", err)
+	}
+
+	expected := map[string]interface{}{
+		"primary":   "a_value_v1",
+		"secondary": "a_second_value_v2",
+	}
+	if !reflect.DeepEqual(res, expected) {
+		t.Errorf("expected: %v, got: %v", expected, res)
+	}
+}
+
+func TestAzGetSecretsKeyFromTagCollision(t *testing.T) {
+	client := newMockClientProxy("", "https://myvaultname.vault.azure.net/secrets/")
+	client.tags = map[string]map[string]*string{
+		"simple": {"alias": toPtr("shared")},
+		"second": {"alias": toPtr("shared")},
+	}
+	keyVault := &backends.AzureKeyVault{
+		ClientBuilder: func(vaultURL string, credential azcore.TokenCredential, options *azsecrets.ClientOptions) (backends.AzSecretsClient, error) {
+			return client, nil
+		},
+	}
+
+	if _, err := keyVault.GetSecrets("keyvault", "", map[string]string{"avp_azkv_key_from_tag": "alias"}); err == nil {
+		t.Fatal("expected an error from the colliding alias but got none")
 	}
 }
 
@@ -96,6 +507,8 @@ func TestAzGetSecrets(t *testing.T) {
 	}{
 		{"Azure", "https://myvaultname.vault.azure.net/keys/", ""},
 		{"AzureChina", "https://myvaultname.vault.azure.cn/keys/", "azurechina"},
+		{"AzureUSGovernment", "https://myvaultname.vault.usgovcloudapi.net/keys/", "azureusgovernment"},
+		{"AzureGermanCloud", "https://myvaultname.vault.microsoftazure.de/keys/", "azuregermancloud"},
 	}
 
 	for _, tt := range tests {
@@ -122,6 +535,71 @@ func TestAzGetSecrets(t *testing.T) {
 	}
 }
 
+func TestAzGetSecretsConcurrent(t *testing.T) {
+	os.Setenv("AVP_AZ_KV_CONCURRENCY", "8")
+	defer os.Unsetenv("AVP_AZ_KV_CONCURRENCY")
+
+	client := newMockClientProxy("slow", "https://myvaultname.vault.azure.net/secrets/")
+	keyVault := &backends.AzureKeyVault{
+		ClientBuilder: func(vaultURL string, credential azcore.TokenCredential, options *azsecrets.ClientOptions) (backends.AzSecretsClient, error) {
+			return client, nil
+		},
+	}
+
+	res, err := keyVault.GetSecrets("keyvault", "", nil)
+	if err != nil {
+		t.Fatalf("expected 0 errors but got: %s", err)
+	}
+	expected := map[string]interface{}{
+		"simple": "a_value_v1",
+		"second": "a_second_value_v2",
+	}
+	if !reflect.DeepEqual(res, expected) {
+		t.Errorf("expected: %v, got: %v.", expected, res)
+	}
+	// Deterministic stand-in for a wall-clock race: both secrets' GetSecret
+	// calls must have been in flight at once, rather than one at a time.
+	if max := atomic.LoadInt32(&client.maxInFlight); max <= 1 {
+		t.Errorf("expected secrets to be fetched concurrently, max in-flight was %d", max)
+	}
+}
+
+func TestAzGetSecretsFailsOnGetSecretError(t *testing.T) {
+	keyVault := newAzureKeyVaultBackendMock("get_error", "https://myvaultname.vault.azure.net/secrets/")
+
+	_, err := keyVault.GetSecrets("keyvault", "", nil)
+	if err == nil {
+		t.Fatal("expected an error but got none")
+	}
+}
+
+func TestAzGetSecretsHardFailsOnMatchingVersionError(t *testing.T) {
+	// "second/v2" is the version listed in the vault, so a GetSecret error for
+	// it while pinned to that exact version is a real failure, not a missing
+	// historical version, and must not be swallowed.
+	keyVault := newAzureKeyVaultBackendMock("get_error", "https://myvaultname.vault.azure.net/secrets/")
+
+	_, err := keyVault.GetSecrets("keyvault", "v2", nil)
+	if err == nil {
+		t.Fatal("expected an error but got none")
+	}
+}
+
+func TestAzGetSecretsSoftFailsOnMismatchedVersion(t *testing.T) {
+	// "second/v2" is the version listed in the vault, so a GetSecret error for
+	// a different pinned version just means that name has no such version,
+	// which is expected and shouldn't fail the whole lookup.
+	keyVault := newAzureKeyVaultBackendMock("get_error", "https://myvaultname.vault.azure.net/secrets/")
+
+	res, err := keyVault.GetSecrets("keyvault", "v1", nil)
+	if err != nil {
+		t.Fatalf("expected 0 errors but got: %s", err)
+	}
+	if _, ok := res["second"]; ok {
+		t.Errorf("expected second to be skipped, got: %v", res["second"])
+	}
+}
+
 func TestAzGetSecret(t *testing.T) {
 	tests := []struct {
 		name         string
@@ -130,6 +608,8 @@ func TestAzGetSecret(t *testing.T) {
 	}{
 		{"Azure", "https://myvaultname.vault.azure.net/keys/", ""},
 		{"AzureChina", "https://myvaultname.vault.azure.cn/keys/", "azurechina"},
+		{"AzureUSGovernment", "https://myvaultname.vault.usgovcloudapi.net/keys/", "azureusgovernment"},
+		{"AzureGermanCloud", "https://myvaultname.vault.microsoftazure.de/keys/", "azuregermancloud"},
 	}
 
 	for _, tt := range tests {
@@ -149,4 +629,131 @@ func TestAzGetSecret(t *testing.T) {
 			}
 		})
 	}
-}
\ No newline at end of file
+}
+
+func TestAzGetSecretEndpointOverride(t *testing.T) {
+	os.Setenv("AVP_AZ_KV_ENDPOINT_OVERRIDE", "privatelink.vaultcore.azure.net")
+	defer os.Unsetenv("AVP_AZ_KV_ENDPOINT_OVERRIDE")
+
+	var gotVaultURLs []string
+	keyVault := &backends.AzureKeyVault{
+		ClientBuilder: func(vaultURL string, credential azcore.TokenCredential, options *azsecrets.ClientOptions) (backends.AzSecretsClient, error) {
+			gotVaultURLs = append(gotVaultURLs, vaultURL)
+			return newMockClientProxy("", ""), nil
+		},
+	}
+
+	// Two distinct vault names must still resolve to two distinct endpoints
+	// under the override, not collapse onto a single vault.
+	for _, kvpath := range []string{"keyvault", "keyvault2"} {
+		data, err := keyVault.GetIndividualSecret(kvpath, "simple", "", nil)
+		if err != nil {
+			t.Fatalf("expected 0 errors but got: %s", err)
+		}
+		expected := "a_value_v1"
+		if !reflect.DeepEqual(expected, data) {
+			t.Errorf("expected: %s, got: %s.", expected, data)
+		}
+	}
+
+	expectedURLs := []string{
+		"https://keyvault.privatelink.vaultcore.azure.net",
+		"https://keyvault2.privatelink.vaultcore.azure.net",
+	}
+	if !reflect.DeepEqual(expectedURLs, gotVaultURLs) {
+		t.Errorf("expected distinct vault URLs %v, got %v", expectedURLs, gotVaultURLs)
+	}
+}
+
+func TestAzGetIndividualCertificate(t *testing.T) {
+	keyVault := newAzureKeyVaultBackendMock("", "")
+
+	data, err := keyVault.GetIndividualSecret("keyvault/certificates", "mycert", "", nil)
+	if err != nil {
+		t.Fatalf("expected 0 errors but got: %s", err)
+	}
+
+	cert, ok := data.(map[string]interface{})
+	if !ok {
+		t.Fatalf("expected a map of certificate material, got: %T", data)
+	}
+	if _, ok := cert["certificate"]; !ok {
+		t.Errorf("expected a PEM-encoded certificate in the result, got: %v", cert)
+	}
+}
+
+func TestAzGetIndividualKey(t *testing.T) {
+	keyVault := newAzureKeyVaultBackendMock("", "")
+
+	data, err := keyVault.GetIndividualSecret("keyvault/keys", "mykey", "", nil)
+	if err != nil {
+		t.Fatalf("expected 0 errors but got: %s", err)
+	}
+
+	key, ok := data.(*azkeys.JSONWebKey)
+	if !ok {
+		t.Fatalf("expected a JSON Web Key, got: %T", data)
+	}
+	if key.KID == nil || *key.KID != "mykey" {
+		t.Errorf("expected key id mykey, got: %v", key.KID)
+	}
+}
+
+func TestAzGetCertificatesIncludeDisabled(t *testing.T) {
+	keyVault := newAzureKeyVaultBackendMock("", "")
+
+	data, err := keyVault.GetSecrets("keyvault/certificates", "", nil)
+	if err != nil {
+		t.Fatalf("expected 0 errors but got: %s", err)
+	}
+	if _, ok := data["mycert"]; !ok {
+		t.Errorf("expected mycert in result, got: %v", data)
+	}
+	if _, ok := data["hiddencert"]; ok {
+		t.Errorf("expected hiddencert to be filtered out by default, got: %v", data)
+	}
+
+	data, err = keyVault.GetSecrets("keyvault/certificates", "", map[string]string{"avp_azkv_include_disabled": "true"})
+	if err != nil {
+		t.Fatalf("expected 0 errors but got: %s", err)
+	}
+	if _, ok := data["hiddencert"]; !ok {
+		t.Errorf("expected avp_azkv_include_disabled=true to surface hiddencert, got: %v", data)
+	}
+}
+
+func TestAzGetKeysIncludeDisabled(t *testing.T) {
+	keyVault := newAzureKeyVaultBackendMock("", "")
+
+	data, err := keyVault.GetSecrets("keyvault/keys", "", nil)
+	if err != nil {
+		t.Fatalf("expected 0 errors but got: %s", err)
+	}
+	if _, ok := data["mykey"]; !ok {
+		t.Errorf("expected mykey in result, got: %v", data)
+	}
+	if _, ok := data["hiddenkey"]; ok {
+		t.Errorf("expected hiddenkey to be filtered out by default, got: %v", data)
+	}
+
+	data, err = keyVault.GetSecrets("keyvault/keys", "", map[string]string{"avp_azkv_include_disabled": "true"})
+	if err != nil {
+		t.Fatalf("expected 0 errors but got: %s", err)
+	}
+	if _, ok := data["hiddenkey"]; !ok {
+		t.Errorf("expected avp_azkv_include_disabled=true to surface hiddenkey, got: %v", data)
+	}
+}
+
+func TestAzGetSecretsByKind(t *testing.T) {
+	keyVault := newAzureKeyVaultBackendMock("", "")
+
+	res, err := keyVault.GetSecrets("keyvault", "", map[string]string{"avp_azkv_kind": "certificate"})
+	if err != nil {
+		t.Fatalf("expected 0 errors but got: %s", err)
+	}
+
+	if _, ok := res["mycert"]; !ok {
+		t.Errorf("expected certificate mycert in the result, got: %v", res)
+	}
+}